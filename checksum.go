@@ -0,0 +1,136 @@
+/**
+ * Open Mirror Center Controller
+ * Copyright (C) 2024 Kevin Z <zyxkad@gmail.com>
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Affero General Public License as published
+ *  by the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU Affero General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Affero General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type FileHash struct {
+	Algo string // "md5", "sha1", "sha256" or "sha512"
+	Hash string // lower case hex
+}
+
+// ParseChecksumFile parses GNU coreutils style (`<hash>  <file>`, with optional `*` binary marker),
+// BSD tagged style (`SHA256 (<file>) = <hash>`), and single-hash files.
+// Backslash-escaped lines are unescaped the same way coreutils writes them.
+// A single-hash file is returned under the empty path.
+func ParseChecksumFile(r io.Reader) (map[string]FileHash, error) {
+	result := make(map[string]FileHash)
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	bareLine := 0 // line number of the single-hash entry, if any
+	for sc.Scan() {
+		lineNo++
+		line := sc.Text()
+		if lineNo == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		path, fh, err := parseChecksumLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if path == "" {
+			if len(result) > 0 && bareLine == 0 {
+				return nil, fmt.Errorf("line %d: %w", lineNo, errMissingFileName)
+			}
+			bareLine = lineNo
+		} else if bareLine != 0 {
+			return nil, fmt.Errorf("line %d: %w", bareLine, errMissingFileName)
+		}
+		if old, ok := result[path]; ok && old != fh {
+			return nil, fmt.Errorf("line %d: duplicate entry for %q", lineNo, path)
+		}
+		result[path] = fh
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+var errMissingFileName = errors.New("missing file name")
+
+var checksumUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n", `\r`, "\r")
+
+func parseChecksumLine(line string) (path string, fh FileHash, err error) {
+	// coreutils prefixes a line with a backslash when the file name contains a backslash or a newline,
+	// in both the GNU and the BSD tagged format
+	if line[0] != '\\' {
+		return parseChecksumEntry(line)
+	}
+	if path, fh, err = parseChecksumEntry(line[1:]); err != nil {
+		return "", fh, err
+	}
+	return checksumUnescaper.Replace(path), fh, nil
+}
+
+func parseChecksumEntry(line string) (path string, fh FileHash, err error) {
+	// BSD tagged format: ALGO (path) = hash
+	if i := strings.Index(line, " ("); i > 0 && !strings.ContainsRune(line[:i], ' ') {
+		if j := strings.LastIndex(line, ") = "); j > i {
+			algo := strings.ToLower(strings.ReplaceAll(line[:i], "-", ""))
+			path = line[i+2 : j]
+			hash := strings.ToLower(line[j+4:])
+			if want := algoByHexLen(len(hash)); want == "" || want != algo || !isHex(hash) {
+				return "", fh, fmt.Errorf("invalid %s hash %q", line[:i], hash)
+			}
+			return path, FileHash{Algo: algo, Hash: hash}, nil
+		}
+	}
+	// GNU format: hash, a space, then a space (text) or '*' (binary), then the file name
+	hash, rest, _ := strings.Cut(line, " ")
+	hash = strings.ToLower(hash)
+	algo := algoByHexLen(len(hash))
+	if algo == "" || !isHex(hash) {
+		return "", fh, fmt.Errorf("invalid hash %q", hash)
+	}
+	if len(rest) > 0 && (rest[0] == ' ' || rest[0] == '*') {
+		rest = rest[1:]
+	}
+	return rest, FileHash{Algo: algo, Hash: hash}, nil
+}
+
+func algoByHexLen(n int) string {
+	switch n {
+	case 32:
+		return "md5"
+	case 40:
+		return "sha1"
+	case 64:
+		return "sha256"
+	case 128:
+		return "sha512"
+	}
+	return ""
+}
+
+func isHex(s string) bool {
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,125 @@
+/**
+ * Open Mirror Center Controller
+ * Copyright (C) 2024 Kevin Z <zyxkad@gmail.com>
+ *
+ *  This program is free software: you can redistribute it and/or modify
+ *  it under the terms of the GNU Affero General Public License as published
+ *  by the Free Software Foundation, either version 3 of the License, or
+ *  (at your option) any later version.
+ *
+ *  This program is distributed in the hope that it will be useful,
+ *  but WITHOUT ANY WARRANTY; without even the implied warranty of
+ *  MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ *  GNU Affero General Public License for more details.
+ *
+ *  You should have received a copy of the GNU Affero General Public License
+ *  along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"maps"
+	"strings"
+	"testing"
+)
+
+const (
+	testMD5    = "d41d8cd98f00b204e9800998ecf8427e"
+	testSHA1   = "da39a3ee5e6b4b0d3255bfef95601890afd80709"
+	testSHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	testSHA512 = "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e"
+)
+
+func TestParseChecksumFile(t *testing.T) {
+	sha256 := FileHash{Algo: "sha256", Hash: testSHA256}
+	data := []struct {
+		name string
+		in   string
+		want map[string]FileHash
+	}{
+		{"gnu", testSHA256 + "  a.txt\n" + testSHA512 + "  dir/b.bin\n", map[string]FileHash{
+			"a.txt":     sha256,
+			"dir/b.bin": {Algo: "sha512", Hash: testSHA512},
+		}},
+		{"binary marker", testMD5 + " *x.jar\n", map[string]FileHash{
+			"x.jar": {Algo: "md5", Hash: testMD5},
+		}},
+		{"upper case", strings.ToUpper(testSHA1) + "  a\n", map[string]FileHash{
+			"a": {Algo: "sha1", Hash: testSHA1},
+		}},
+		{"spaces in name", testSHA256 + "  odd (1) = x\n", map[string]FileHash{
+			"odd (1) = x": sha256,
+		}},
+		{"gnu escaped", `\` + testSHA256 + `  a\nb\\c` + "\n", map[string]FileHash{
+			"a\nb\\c": sha256,
+		}},
+		{"bsd", "SHA256 (a.txt) = " + testSHA256 + "\nSHA1 (foo (1).txt) = " + testSHA1 + "\n", map[string]FileHash{
+			"a.txt":       sha256,
+			"foo (1).txt": {Algo: "sha1", Hash: testSHA1},
+		}},
+		{"bsd escaped", `\SHA256 (a\nb) = ` + testSHA256 + "\n", map[string]FileHash{
+			"a\nb": sha256,
+		}},
+		{"single hash", testSHA256 + "\n", map[string]FileHash{
+			"": sha256,
+		}},
+		{"single hash repeated", testSHA256 + "\n" + testSHA256 + "\n", map[string]FileHash{
+			"": sha256,
+		}},
+		{"crlf and whitespace", "  " + testSHA256 + "  a.txt \r\n\t\r\n" + testMD5 + "  b\r\n", map[string]FileHash{
+			"a.txt": sha256,
+			"b":     {Algo: "md5", Hash: testMD5},
+		}},
+		{"bom", "\ufeff" + testSHA256 + "  a.txt\n", map[string]FileHash{
+			"a.txt": sha256,
+		}},
+		{"comments", "# generated\n" + testSHA256 + "  a.txt\n  # trailing\n", map[string]FileHash{
+			"a.txt": sha256,
+		}},
+		{"repeated entry", testSHA256 + "  a\n" + testSHA256 + " *a\n", map[string]FileHash{
+			"a": sha256,
+		}},
+		{"empty", "", map[string]FileHash{}},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			got, err := ParseChecksumFile(strings.NewReader(d.in))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !maps.Equal(got, d.want) {
+				t.Errorf("Expected %v, got %v", d.want, got)
+			}
+		})
+	}
+}
+
+func TestParseChecksumFileErrors(t *testing.T) {
+	data := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{"bad hex", strings.Repeat("z", 64) + "  a\n", "line 1: invalid hash"},
+		{"unknown length", "abcdef  a\n", "line 1: invalid hash"},
+		{"bsd length mismatch", "SHA256 (a) = " + testSHA1 + "\n", "line 1: invalid SHA256 hash"},
+		{"bsd unknown algo", "CRC32 (a) = " + testSHA1 + "\n", "line 1: invalid CRC32 hash"},
+		{"bsd bad hex", "SHA1 (a) = " + strings.Repeat("g", 40) + "\n", "line 1: invalid SHA1 hash"},
+		{"single hash after named", testSHA256 + "  a\n\n" + testSHA256 + "\n", "line 3: missing file name"},
+		{"single hash before named", "# x\n" + testSHA256 + "\n" + testSHA256 + "  a\n", "line 2: missing file name"},
+		{"duplicate", testSHA256 + "  a\n" + testSHA1 + "  b\n" + testMD5 + "  a\n", `line 3: duplicate entry for "a"`},
+		{"duplicate single hash", testSHA256 + "\n" + testMD5 + "\n", `line 2: duplicate entry for ""`},
+	}
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			got, err := ParseChecksumFile(strings.NewReader(d.in))
+			if err == nil {
+				t.Fatalf("Expected error %q, got result %v", d.err, got)
+			}
+			if !strings.HasPrefix(err.Error(), d.err) {
+				t.Errorf("Expected error %q, got %q", d.err, err.Error())
+			}
+		})
+	}
+}